@@ -0,0 +1,113 @@
+/*
+ * Copyright (c) 2021, John-Alan Simmons
+ * All rights reserved.
+ *
+ * Redistribution and use in source and binary forms, with or without
+ * modification, are permitted provided that the following conditions are met:
+ *
+ * 1. Redistributions of source code must retain the above copyright notice,
+ *    this list of conditions and the following disclaimer.
+ * 2. Redistributions in binary form must reproduce the above copyright
+ *    notice, this list of conditions and the following disclaimer in the
+ *    documentation and/or other materials provided with the distribution.
+ * 3. Neither the name of mosquitto nor the names of its
+ *    contributors may be used to endorse or promote products derived from
+ *    this software without specific prior written permission.
+ *
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+ * AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+ * IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+ * ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR CONTRIBUTORS BE
+ * LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+ * CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+ * SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+ * INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+ * CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+ * ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+ * POSSIBILITY OF SUCH DAMAGE.
+ */
+
+package jsonmerge
+
+import (
+	"bytes"
+
+	"github.com/valyala/fastjson"
+)
+
+// EqualDocuments parses both documents and reports whether they are
+// structurally equal, as defined by Equal.
+func EqualDocuments(aData, bData []byte) (bool, error) {
+	a, err := fastjson.ParseBytes(aData)
+	if err != nil {
+		return false, err
+	}
+
+	b, err := fastjson.ParseBytes(bData)
+	if err != nil {
+		return false, err
+	}
+
+	return Equal(a, b), nil
+}
+
+// Equal reports whether a and b hold structurally equal JSON values.
+// Object members are compared regardless of their order, arrays are
+// compared element by element, and numbers are compared by value. When an
+// object repeats a key, only the first value for that key is compared.
+//
+// Numbers are compared as float64, so 10, 10.0 and 1e1 are all equal.
+// Numbers that differ only beyond float64 precision, such as integers
+// above 2^53, may also compare equal.
+func Equal(a, b *fastjson.Value) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	if a.Type() != b.Type() {
+		return false
+	}
+
+	switch a.Type() {
+	case fastjson.TypeObject:
+		aObj, _ := a.Object()
+		bObj, _ := b.Object()
+
+		// fastjson keeps duplicate keys, so objects are compared by their
+		// distinct keys, each resolved to its first value like GetValue
+		// does. Both key sets are walked, so Len, which counts duplicates,
+		// is not compared.
+		equal := true
+		aObj.Visit(func(key []byte, _ *fastjson.Value) {
+			if equal {
+				equal = Equal(aObj.Get(string(key)), bObj.Get(string(key)))
+			}
+		})
+		bObj.Visit(func(key []byte, _ *fastjson.Value) {
+			if equal {
+				equal = aObj.Get(string(key)) != nil
+			}
+		})
+		return equal
+	case fastjson.TypeArray:
+		aAry, _ := a.Array()
+		bAry, _ := b.Array()
+		if len(aAry) != len(bAry) {
+			return false
+		}
+
+		for i := range aAry {
+			if !Equal(aAry[i], bAry[i]) {
+				return false
+			}
+		}
+		return true
+	case fastjson.TypeString:
+		return bytes.Equal(a.GetStringBytes(), b.GetStringBytes())
+	case fastjson.TypeNumber:
+		return a.GetFloat64() == b.GetFloat64()
+	default:
+		// true, false and null carry no value beyond their type
+		return true
+	}
+}
@@ -0,0 +1,105 @@
+/*
+ * Copyright (c) 2021, John-Alan Simmons
+ * All rights reserved.
+ *
+ * Redistribution and use in source and binary forms, with or without
+ * modification, are permitted provided that the following conditions are met:
+ *
+ * 1. Redistributions of source code must retain the above copyright notice,
+ *    this list of conditions and the following disclaimer.
+ * 2. Redistributions in binary form must reproduce the above copyright
+ *    notice, this list of conditions and the following disclaimer in the
+ *    documentation and/or other materials provided with the distribution.
+ * 3. Neither the name of mosquitto nor the names of its
+ *    contributors may be used to endorse or promote products derived from
+ *    this software without specific prior written permission.
+ *
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+ * AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+ * IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+ * ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR CONTRIBUTORS BE
+ * LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+ * CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+ * SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+ * INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+ * CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+ * ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+ * POSSIBILITY OF SUCH DAMAGE.
+ */
+
+package jsonmerge_test
+
+import (
+	"testing"
+
+	merge "github.com/lens-vm/jsonmerge"
+	"github.com/valyala/fastjson"
+)
+
+func TestEqual(t *testing.T) {
+	cases := []struct {
+		a, b string
+		exp  bool
+	}{
+		{`{"a":1,"b":2}`, `{"b":2,"a":1}`, true},
+		{`{"a":1,"b":2}`, `{"a":1}`, false},
+		{`{"a":1}`, `{"b":1}`, false},
+		{`{"a":1,"a":1}`, `{"a":1,"b":1}`, false},
+		{`{"a":1,"a":2}`, `{"a":1,"a":2}`, true},
+		{`{"a":1,"a":2}`, `{"a":1,"a":3}`, true},
+		{`{"a":1,"a":1}`, `{"a":1}`, true},
+		{`{"a":1,"a":2}`, `{"a":2}`, false},
+		{`{"a":{"x":[1,{"y":null}]}}`, `{ "a" : { "x" : [ 1 , { "y" : null } ] } }`, true},
+		{`[[1,2],[3]]`, `[[1,2],[3]]`, true},
+		{`[[1,2],[3]]`, `[[2,1],[3]]`, false},
+		{`[1,2]`, `[1,2,3]`, false},
+		{`"a\u0062"`, `"ab"`, true},
+		{`"1"`, `1`, false},
+		{`1.0`, `1`, true},
+		{`10`, `1e1`, true},
+		{`10.0`, `1E+1`, true},
+		{`0.30000000000000004`, `0.3`, false},
+		{`0.1`, `1e-1`, true},
+		{`9007199254740993`, `9007199254740992`, true},
+		{`9007199254740994`, `9007199254740992`, false},
+		{`-0`, `0`, true},
+		{`true`, `true`, true},
+		{`true`, `false`, false},
+		{`null`, `null`, true},
+		{`null`, `{}`, false},
+	}
+
+	for _, c := range cases {
+		a := fastjson.MustParse(c.a)
+		b := fastjson.MustParse(c.b)
+
+		if out := merge.Equal(a, b); out != c.exp {
+			t.Errorf("Equal(%s, %s): expected %v, got %v", c.a, c.b, c.exp, out)
+		}
+		if out := merge.Equal(b, a); out != c.exp {
+			t.Errorf("Equal(%s, %s): expected %v, got %v", c.b, c.a, c.exp, out)
+		}
+	}
+}
+
+func TestEqualDocuments(t *testing.T) {
+	eq, err := merge.EqualDocuments([]byte(`{"a":1,"b":{"c":[1,2]}}`), []byte(`{"b":{"c":[1,2]},"a":1}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !eq {
+		t.Errorf("expected documents with reordered keys to be equal")
+	}
+
+	eq, err = merge.EqualDocuments([]byte(`{"a":[[1,2],[3]]}`), []byte(`{"a":[[1,2],[3,4]]}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if eq {
+		t.Errorf("expected documents with different nested arrays to differ")
+	}
+
+	if _, err := merge.EqualDocuments([]byte(`{`), []byte(`{}`)); err == nil {
+		t.Errorf("expected an error for bad json")
+	}
+}
@@ -149,11 +149,26 @@ func MergeMergePatches(patch1Data, patch2Data []byte) ([]byte, error) {
 	return doMergePatch(patch1Data, patch2Data, true)
 }
 
-// MergePatch merges the patchData into the docData.
+// MergePatch merges the patchData into the docData. Values untouched by
+// the patch, including numbers, keep their original formatting.
 func MergePatch(docData, patchData []byte) ([]byte, error) {
 	return doMergePatch(docData, patchData, false)
 }
 
+// MergePatchFast merges the patch into the doc, operating directly on
+// already parsed fastjson values. The doc may be modified in place, so
+// callers should use the returned value as the merged result. The patch
+// is copied before merging: it is left untouched and the result never
+// shares values with it.
+func MergePatchFast(doc, patch *fastjson.Value) (*fastjson.Value, error) {
+	patch, err := fastjson.ParseBytes(patch.MarshalTo(nil))
+	if err != nil {
+		return nil, err
+	}
+
+	return mergeValues(doc, patch, false)
+}
+
 func doMergePatch(docData, patchData []byte, mergeMerge bool) ([]byte, error) {
 	doc, err := fastjson.ParseBytes(docData)
 	if err != nil {
@@ -165,6 +180,15 @@ func doMergePatch(docData, patchData []byte, mergeMerge bool) ([]byte, error) {
 		return nil, err
 	}
 
+	out, err := mergeValues(doc, patch, mergeMerge)
+	if err != nil {
+		return nil, err
+	}
+
+	return out.MarshalTo(nil), nil
+}
+
+func mergeValues(doc, patch *fastjson.Value, mergeMerge bool) (*fastjson.Value, error) {
 	_, docErr := doc.Object()
 	_, patchErr := patch.Object()
 
@@ -172,32 +196,25 @@ func doMergePatch(docData, patchData []byte, mergeMerge bool) ([]byte, error) {
 		// Not an error, just not a doc, so we turn straight into the patch
 		if patchErr == nil {
 			if mergeMerge {
-				doc = patch
-			} else {
-				doc, err = pruneDocNulls(patch)
-			}
-		} else {
-			patchAry, patchErr := patch.Array()
-
-			if patchErr != nil {
-				return nil, ErrBadJSONPatch
+				return patch, nil
 			}
+			return pruneDocNulls(patch)
+		}
 
-			pruneAryNulls(&patchAry)
+		patchAry, patchErr := patch.Array()
 
-			out := patch.MarshalTo(nil)
+		if patchErr != nil {
+			return nil, ErrBadJSONPatch
+		}
 
-			// if patchErr != nil {
-			// 	return nil, ErrBadJSONPatch
-			// }
+		pruneAryNulls(&patchAry)
 
-			return out, nil
-		}
-	} else {
-		mergeDocs(doc, patch, mergeMerge)
+		return patch, nil
 	}
 
-	return doc.MarshalTo(nil), nil
+	mergeDocs(doc, patch, mergeMerge)
+
+	return doc, nil
 }
 
 // // resemblesJSONArray indicates whether the byte-slice "appears" to be
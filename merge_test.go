@@ -31,11 +31,13 @@ package jsonmerge_test
 
 import (
 	"encoding/json"
+	"errors"
 	"reflect"
 	"strings"
 	"testing"
 
 	merge "github.com/lens-vm/jsonmerge"
+	"github.com/valyala/fastjson"
 )
 
 func mergePatch(doc, patch string) string {
@@ -190,6 +192,43 @@ func TestMergePatchRFCCases(t *testing.T) {
 	}
 }
 
+func TestMergePatchFastRFCCases(t *testing.T) {
+	for i, c := range rfcTests {
+		doc := fastjson.MustParse(c.target)
+		patch := fastjson.MustParse(c.patch)
+
+		res, err := merge.MergePatchFast(doc, patch)
+		if err != nil {
+			t.Fatalf("case[%d], unexpected error: %s", i, err)
+		}
+
+		out := string(res.MarshalTo(nil))
+		if !compareJSON(out, c.expected) {
+			t.Errorf("case[%d], patch '%s' did not apply properly to '%s'. expected:\n'%s'\ngot:\n'%s'", i, c.patch, c.target, c.expected, out)
+		}
+	}
+}
+
+func TestMergePatchFastLeavesPatchUntouched(t *testing.T) {
+	doc := fastjson.MustParse(`{"a":1}`)
+	patch := fastjson.MustParse(`{"b":{"c":null,"d":1}}`)
+
+	res, err := merge.MergePatchFast(doc, patch)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if out := string(patch.MarshalTo(nil)); out != `{"b":{"c":null,"d":1}}` {
+		t.Fatalf("patch was modified by the merge: %s", out)
+	}
+
+	patch.Get("b").Set("e", fastjson.MustParse(`2`))
+
+	if out := string(res.MarshalTo(nil)); out != `{"a":1,"b":{"d":1}}` {
+		t.Fatalf("result shares values with the patch: %s", out)
+	}
+}
+
 var rfcFailTests = `
      {"a":"foo"}  |   null
      {"a":"foo"}  |   "bar"
@@ -217,6 +256,32 @@ func TestMergePatchFailRFCCases(t *testing.T) {
 
 }
 
+func TestMergePatchFastFailRFCCases(t *testing.T) {
+	tests := strings.Split(rfcFailTests, "\n")
+
+	for _, c := range tests {
+		if strings.TrimSpace(c) == "" {
+			continue
+		}
+
+		parts := strings.SplitN(c, "|", 2)
+
+		doc := fastjson.MustParse(strings.TrimSpace(parts[0]))
+		pat := fastjson.MustParse(strings.TrimSpace(parts[1]))
+		before := string(doc.MarshalTo(nil))
+
+		out, err := merge.MergePatchFast(doc, pat)
+
+		if !errors.Is(err, merge.ErrBadJSONPatch) {
+			t.Errorf("expected ErrBadJSONPatch, got: %v, %v", err, out)
+		}
+
+		if after := string(doc.MarshalTo(nil)); after != before {
+			t.Errorf("doc was modified on error, expected %s, got %s", before, after)
+		}
+	}
+}
+
 // func TestResembleJSONArray(t *testing.T) {
 // 	testCases := []struct {
 // 		input    []byte
@@ -662,6 +727,30 @@ func TestMergePatchReplaceKeyNotEscaping(t *testing.T) {
 	}
 }
 
+func TestMergePatchEscapedKeys(t *testing.T) {
+	doc := `{ "a\"b": 1, "日本語": 2, "tab\there": 3, "back\\slash": 4 }`
+	pat := `{ "a\"b": 5, "日本語": null, "tab\there": { "x": 1 }, "newé": 6 }`
+	exp := `{ "a\"b": 5, "tab\there": { "x": 1 }, "back\\slash": 4, "newé": 6 }`
+
+	res := mergePatch(doc, pat)
+
+	if !compareJSON(exp, res) {
+		t.Fatalf("Escaped keys were not merged properly, expected %s, got %s", exp, res)
+	}
+}
+
+func TestMergePatchPreservesNumberFormatting(t *testing.T) {
+	doc := `{"n":100.0,"e":1e2,"big":9999999999999999999}`
+	pat := `{"a":1}`
+	exp := `{"n":100.0,"e":1e2,"big":9999999999999999999,"a":1}`
+
+	res := mergePatch(doc, pat)
+
+	if res != exp {
+		t.Fatalf("Number formatting was not preserved, expected %s, got %s", exp, res)
+	}
+}
+
 func TestMergeMergePatches(t *testing.T) {
 	cases := []struct {
 		demonstrates string
@@ -0,0 +1,286 @@
+/*
+ * Copyright (c) 2021, John-Alan Simmons
+ * All rights reserved.
+ *
+ * Redistribution and use in source and binary forms, with or without
+ * modification, are permitted provided that the following conditions are met:
+ *
+ * 1. Redistributions of source code must retain the above copyright notice,
+ *    this list of conditions and the following disclaimer.
+ * 2. Redistributions in binary form must reproduce the above copyright
+ *    notice, this list of conditions and the following disclaimer in the
+ *    documentation and/or other materials provided with the distribution.
+ * 3. Neither the name of mosquitto nor the names of its
+ *    contributors may be used to endorse or promote products derived from
+ *    this software without specific prior written permission.
+ *
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+ * AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+ * IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+ * ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR CONTRIBUTORS BE
+ * LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+ * CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+ * SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+ * INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+ * CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+ * ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+ * POSSIBILITY OF SUCH DAMAGE.
+ */
+
+package jsonmerge
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/valyala/fastjson"
+)
+
+var (
+	ErrInvalidPointer = errors.New("invalid JSON pointer")
+	ErrNotContainer   = errors.New("value is not an object or array")
+)
+
+var (
+	rfc6901Encoder = strings.NewReplacer("~", "~0", "/", "~1")
+	rfc6901Decoder = strings.NewReplacer("~1", "/", "~0", "~")
+)
+
+// decodePatchKey unescapes a reference token. A ~ must be followed by
+// 0 or 1, any other use is rejected.
+func decodePatchKey(k string) (string, error) {
+	for i := 0; i < len(k); i++ {
+		if k[i] == '~' && (i+1 == len(k) || (k[i+1] != '0' && k[i+1] != '1')) {
+			return "", ErrInvalidPointer
+		}
+	}
+
+	return rfc6901Decoder.Replace(k), nil
+}
+
+// EncodePointerToken escapes a single object key or array index so it
+// can be used as a reference token in a JSON pointer.
+func EncodePointerToken(key string) string {
+	return rfc6901Encoder.Replace(key)
+}
+
+// EncodePointer builds a JSON pointer from the given unescaped reference
+// tokens. With no tokens it returns "", the pointer to the whole document.
+func EncodePointer(tokens ...string) string {
+	var b strings.Builder
+	for _, token := range tokens {
+		b.WriteByte('/')
+		b.WriteString(EncodePointerToken(token))
+	}
+	return b.String()
+}
+
+// GetValue resolves the RFC 6901 JSON pointer against docData and returns
+// the value found there. The empty pointer "" refers to the whole document.
+func GetValue(docData []byte, pointer string) ([]byte, error) {
+	doc, err := fastjson.ParseBytes(docData)
+	if err != nil {
+		return nil, err
+	}
+
+	val, err := GetValueFast(doc, pointer)
+	if err != nil {
+		return nil, err
+	}
+
+	return val.MarshalTo(nil), nil
+}
+
+// GetValueFast is the same as GetValue, but operates on an already parsed
+// fastjson value. The returned value references memory owned by doc.
+//
+// Pointers in URI fragment form, such as "#/a%2Fb", are also accepted. Each
+// reference token is percent-decoded on its own before the ~0/~1 escapes.
+func GetValueFast(doc *fastjson.Value, pointer string) (*fastjson.Value, error) {
+	path := pointer
+	fragment := strings.HasPrefix(path, "#")
+	if fragment {
+		path = path[1:]
+	}
+
+	if path == "" {
+		return doc, nil
+	}
+
+	if path[0] != '/' {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidPointer, pointer)
+	}
+
+	cur := doc
+	prefix := pointer[:len(pointer)-len(path)]
+	for _, part := range strings.Split(path[1:], "/") {
+		raw := part
+		if fragment {
+			var err error
+			part, err = url.PathUnescape(part)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %q", ErrInvalidPointer, pointer)
+			}
+		}
+		key, err := decodePatchKey(part)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q", err, pointer)
+		}
+
+		switch cur.Type() {
+		case fastjson.TypeObject:
+			obj, _ := cur.Object()
+			cur = obj.Get(key)
+		case fastjson.TypeArray:
+			ary, _ := cur.Array()
+			idx, err := parseArrayIndex(key)
+			if err != nil {
+				return nil, err
+			}
+			if idx >= len(ary) {
+				cur = nil
+			} else {
+				cur = ary[idx]
+			}
+		default:
+			return nil, fmt.Errorf("%w: %q in %s", ErrNotContainer, prefix, pointer)
+		}
+
+		if cur == nil {
+			return nil, fmt.Errorf("%w: %s", ErrMissing, pointer)
+		}
+		prefix += "/" + raw
+	}
+
+	return cur, nil
+}
+
+// GetValueTyped is the same as GetValue, but converts the resolved value
+// into a native Go value: map[string]interface{}, []interface{}, string,
+// float64, bool or nil.
+func GetValueTyped(docData []byte, pointer string) (interface{}, error) {
+	doc, err := fastjson.ParseBytes(docData)
+	if err != nil {
+		return nil, err
+	}
+
+	val, err := GetValueFast(doc, pointer)
+	if err != nil {
+		return nil, err
+	}
+
+	return toInterface(val)
+}
+
+func toInterface(v *fastjson.Value) (interface{}, error) {
+	switch v.Type() {
+	case fastjson.TypeObject:
+		obj, _ := v.Object()
+		out := make(map[string]interface{}, obj.Len())
+
+		var err error
+		obj.Visit(func(key []byte, item *fastjson.Value) {
+			// Keep the first value of a repeated key, as GetValue does.
+			if _, ok := out[string(key)]; ok || err != nil {
+				return
+			}
+			out[string(key)], err = toInterface(item)
+		})
+		if err != nil {
+			return nil, err
+		}
+		return out, nil
+	case fastjson.TypeArray:
+		ary, _ := v.Array()
+		out := make([]interface{}, len(ary))
+		for i, item := range ary {
+			val, err := toInterface(item)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = val
+		}
+		return out, nil
+	case fastjson.TypeString:
+		return string(v.GetStringBytes()), nil
+	case fastjson.TypeNumber:
+		return v.Float64()
+	case fastjson.TypeTrue:
+		return true, nil
+	case fastjson.TypeFalse:
+		return false, nil
+	case fastjson.TypeNull:
+		return nil, nil
+	default:
+		return nil, ErrUnknownType
+	}
+}
+
+// Flatten returns the JSON pointer of every leaf value in docData, mapped
+// to the marshalled leaf. Empty objects and arrays are treated as leaves
+// so that no part of the document is lost.
+func Flatten(docData []byte) (map[string][]byte, error) {
+	doc, err := fastjson.ParseBytes(docData)
+	if err != nil {
+		return nil, err
+	}
+
+	out := map[string][]byte{}
+	flatten(out, "", doc)
+
+	return out, nil
+}
+
+func flatten(out map[string][]byte, pointer string, v *fastjson.Value) {
+	switch v.Type() {
+	case fastjson.TypeObject:
+		obj, _ := v.Object()
+		if obj.Len() > 0 {
+			// Only descend into the first value of a repeated key, so every
+			// pointer resolves with GetValue to the value it is mapped to.
+			seen := make(map[string]bool, obj.Len())
+			obj.Visit(func(key []byte, item *fastjson.Value) {
+				if seen[string(key)] {
+					return
+				}
+				seen[string(key)] = true
+				flatten(out, pointer+"/"+EncodePointerToken(string(key)), item)
+			})
+			return
+		}
+	case fastjson.TypeArray:
+		ary, _ := v.Array()
+		if len(ary) > 0 {
+			for i, item := range ary {
+				flatten(out, pointer+"/"+strconv.Itoa(i), item)
+			}
+			return
+		}
+	}
+
+	out[pointer] = v.MarshalTo(nil)
+}
+
+// parseArrayIndex parses an array index reference token, which must be
+// either "0" or a run of digits without a leading zero.
+func parseArrayIndex(key string) (int, error) {
+	if key == "" || (len(key) > 1 && key[0] == '0') {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidIndex, key)
+	}
+
+	for _, c := range key {
+		if c < '0' || c > '9' {
+			return 0, fmt.Errorf("%w: %q", ErrInvalidIndex, key)
+		}
+	}
+
+	idx, err := strconv.Atoi(key)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidIndex, key)
+	}
+
+	return idx, nil
+}
@@ -0,0 +1,418 @@
+/*
+ * Copyright (c) 2021, John-Alan Simmons
+ * All rights reserved.
+ *
+ * Redistribution and use in source and binary forms, with or without
+ * modification, are permitted provided that the following conditions are met:
+ *
+ * 1. Redistributions of source code must retain the above copyright notice,
+ *    this list of conditions and the following disclaimer.
+ * 2. Redistributions in binary form must reproduce the above copyright
+ *    notice, this list of conditions and the following disclaimer in the
+ *    documentation and/or other materials provided with the distribution.
+ * 3. Neither the name of mosquitto nor the names of its
+ *    contributors may be used to endorse or promote products derived from
+ *    this software without specific prior written permission.
+ *
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+ * AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+ * IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+ * ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR CONTRIBUTORS BE
+ * LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+ * CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+ * SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+ * INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+ * CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+ * ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+ * POSSIBILITY OF SUCH DAMAGE.
+ */
+
+package jsonmerge_test
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	merge "github.com/lens-vm/jsonmerge"
+)
+
+func TestGetValue(t *testing.T) {
+	doc := `{"foo": {"bar": [1, {"baz": "qux"}]}, "a/b": 1, "m~n": 2}`
+
+	cases := []struct {
+		pointer string
+		exp     string
+	}{
+		{"", doc},
+		{"/foo/bar", `[1, {"baz": "qux"}]`},
+		{"/foo/bar/0", `1`},
+		{"/foo/bar/1/baz", `"qux"`},
+		{"/a~1b", `1`},
+		{"/m~0n", `2`},
+	}
+
+	for _, c := range cases {
+		out, err := merge.GetValue([]byte(doc), c.pointer)
+		if err != nil {
+			t.Errorf("pointer %q: unexpected error: %s", c.pointer, err)
+			continue
+		}
+
+		if !compareJSONValue(c.exp, string(out)) {
+			t.Errorf("pointer %q: expected %s, got %s", c.pointer, c.exp, out)
+		}
+	}
+}
+
+func TestGetValueTyped(t *testing.T) {
+	doc := []byte(`{"foo": {"bar": [1, "two", {"baz": true}], "qux": null}}`)
+
+	cases := []struct {
+		pointer string
+		exp     interface{}
+	}{
+		{"/foo", map[string]interface{}{
+			"bar": []interface{}{float64(1), "two", map[string]interface{}{"baz": true}},
+			"qux": nil,
+		}},
+		{"/foo/bar/1", "two"},
+		{"/foo/bar/0", float64(1)},
+		{"/foo/bar/2/baz", true},
+		{"/foo/qux", nil},
+	}
+
+	for _, c := range cases {
+		out, err := merge.GetValueTyped(doc, c.pointer)
+		if err != nil {
+			t.Errorf("pointer %q: unexpected error: %s", c.pointer, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(c.exp, out) {
+			t.Errorf("pointer %q: expected %#v, got %#v", c.pointer, c.exp, out)
+		}
+	}
+
+	if _, err := merge.GetValueTyped(doc, "/missing"); !errors.Is(err, merge.ErrMissing) {
+		t.Errorf("expected ErrMissing, got %v", err)
+	}
+}
+
+func TestGetValueTypedDuplicateKeys(t *testing.T) {
+	doc := []byte(`{"a":1,"a":2,"b":{"c":true,"c":false}}`)
+
+	val, err := merge.GetValueTyped(doc, "/a")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if val != float64(1) {
+		t.Errorf("expected 1 for /a, got %#v", val)
+	}
+
+	root, err := merge.GetValueTyped(doc, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	exp := map[string]interface{}{"a": float64(1), "b": map[string]interface{}{"c": true}}
+	if !reflect.DeepEqual(exp, root) {
+		t.Errorf("expected %#v, got %#v", exp, root)
+	}
+}
+
+func TestGetValueEmptyReferenceToken(t *testing.T) {
+	out, err := merge.GetValue([]byte(`{"":"x","a/b":"y"}`), "/")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if string(out) != `"x"` {
+		t.Errorf(`expected "x", got %s`, out)
+	}
+
+	out, err = merge.GetValue([]byte(`{"foo":{"":1}}`), "/foo/")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if string(out) != `1` {
+		t.Errorf(`expected 1, got %s`, out)
+	}
+
+	out, err = merge.GetValue([]byte(`{"":"x","a/b":"y"}`), "/a~1b")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if string(out) != `"y"` {
+		t.Errorf(`expected "y", got %s`, out)
+	}
+}
+
+func TestGetValueEscapedKeys(t *testing.T) {
+	doc := []byte(`{"a\"b": 1, "日本語": 2, "tab\there": 3}`)
+
+	for pointer, exp := range map[string]string{"/a\"b": "1", "/日本語": "2", "/tab\there": "3"} {
+		out, err := merge.GetValue(doc, pointer)
+		if err != nil {
+			t.Errorf("pointer %q: unexpected error: %s", pointer, err)
+			continue
+		}
+		if string(out) != exp {
+			t.Errorf("pointer %q: expected %s, got %s", pointer, exp, out)
+		}
+	}
+}
+
+func TestGetValueMissing(t *testing.T) {
+	doc := []byte(`{"foo": {"bar": [1, 2]}}`)
+
+	for _, pointer := range []string{"/baz", "/foo/baz", "/foo/bar/2"} {
+		_, err := merge.GetValue(doc, pointer)
+		if !errors.Is(err, merge.ErrMissing) {
+			t.Errorf("pointer %q: expected ErrMissing, got %v", pointer, err)
+		}
+	}
+}
+
+func TestGetValueFragment(t *testing.T) {
+	doc := []byte(`{"a/b": 1, "c d": {"%": 2}, "m~n": [3]}`)
+
+	cases := []struct {
+		pointer string
+		exp     string
+	}{
+		{"#", string(doc)},
+		{"#/a%2Fb", `1`},
+		{"#/a~1b", `1`},
+		{"#/c%20d/%25", `2`},
+		{"#/m~0n/0", `3`},
+	}
+
+	for _, c := range cases {
+		out, err := merge.GetValue(doc, c.pointer)
+		if err != nil {
+			t.Errorf("pointer %q: unexpected error: %s", c.pointer, err)
+			continue
+		}
+
+		if !compareJSONValue(c.exp, string(out)) {
+			t.Errorf("pointer %q: expected %s, got %s", c.pointer, c.exp, out)
+		}
+	}
+
+	if _, err := merge.GetValue(doc, "#/a%2"); !errors.Is(err, merge.ErrInvalidPointer) {
+		t.Errorf("expected ErrInvalidPointer, got %v", err)
+	}
+}
+
+func TestGetValuePointerEscapes(t *testing.T) {
+	doc := []byte(`{"~1": 1, "a~b/c": 2, "foo~": 3}`)
+
+	for pointer, exp := range map[string]string{"/~01": "1", "/a~0b~1c": "2", "#/a%7E0b~1c": "2"} {
+		out, err := merge.GetValue(doc, pointer)
+		if err != nil {
+			t.Errorf("pointer %q: unexpected error: %s", pointer, err)
+			continue
+		}
+		if string(out) != exp {
+			t.Errorf("pointer %q: expected %s, got %s", pointer, exp, out)
+		}
+	}
+
+	for _, pointer := range []string{"/foo~", "#/foo~", "#/foo%7E", "#/foo%7E2"} {
+		if _, err := merge.GetValue(doc, pointer); !errors.Is(err, merge.ErrInvalidPointer) {
+			t.Errorf("pointer %q: expected ErrInvalidPointer, got %v", pointer, err)
+		}
+	}
+}
+
+func TestGetValueDeepPointer(t *testing.T) {
+	pointer := strings.Repeat("/a", 50000)
+
+	_, err := merge.GetValue([]byte(`{"a":{"a":{"b":1}}}`), pointer)
+	if !errors.Is(err, merge.ErrMissing) {
+		t.Errorf("expected ErrMissing, got %v", err)
+	}
+}
+
+func TestGetValueNotContainer(t *testing.T) {
+	doc := []byte(`{"a": {"b": 5, "c": ["x"]}}`)
+
+	cases := []struct {
+		pointer, segment string
+	}{
+		{"/a/b/c", `"/a/b"`},
+		{"/a/c/0/d", `"/a/c/0"`},
+		{"#/a/b/c", `"#/a/b"`},
+	}
+
+	for _, c := range cases {
+		_, err := merge.GetValue(doc, c.pointer)
+		if !errors.Is(err, merge.ErrNotContainer) {
+			t.Errorf("pointer %q: expected ErrNotContainer, got %v", c.pointer, err)
+			continue
+		}
+		if errors.Is(err, merge.ErrMissing) {
+			t.Errorf("pointer %q: expected error not to be ErrMissing", c.pointer)
+		}
+		if !strings.Contains(err.Error(), c.segment) {
+			t.Errorf("pointer %q: expected error to name %s, got %s", c.pointer, c.segment, err)
+		}
+	}
+
+	if _, err := merge.GetValue([]byte(`5`), "/a"); !errors.Is(err, merge.ErrNotContainer) {
+		t.Errorf("expected ErrNotContainer for a scalar root, got %v", err)
+	}
+}
+
+func TestGetValueInvalid(t *testing.T) {
+	doc := []byte(`{"foo": [1, 2], "foo~": 1, "foo~2": 2}`)
+
+	for _, pointer := range []string{"foo", "/foo~", "/foo~2", "/foo~/0", "/~a"} {
+		if _, err := merge.GetValue(doc, pointer); !errors.Is(err, merge.ErrInvalidPointer) {
+			t.Errorf("pointer %q: expected ErrInvalidPointer, got %v", pointer, err)
+		}
+	}
+
+	for _, pointer := range []string{"/foo/01", "/foo/-1", "/foo/x"} {
+		if _, err := merge.GetValue(doc, pointer); !errors.Is(err, merge.ErrInvalidIndex) {
+			t.Errorf("pointer %q: expected ErrInvalidIndex, got %v", pointer, err)
+		}
+	}
+}
+
+func TestEncodePointer(t *testing.T) {
+	cases := []struct {
+		tokens []string
+		exp    string
+	}{
+		{nil, ""},
+		{[]string{""}, "/"},
+		{[]string{"foo", "0"}, "/foo/0"},
+		{[]string{"a/b"}, "/a~1b"},
+		{[]string{"m~n"}, "/m~0n"},
+		{[]string{"~1/~0"}, "/~01~1~00"},
+	}
+
+	for _, c := range cases {
+		if out := merge.EncodePointer(c.tokens...); out != c.exp {
+			t.Errorf("tokens %q: expected %q, got %q", c.tokens, c.exp, out)
+		}
+	}
+}
+
+func TestEncodePointerTokenRoundTrip(t *testing.T) {
+	for _, key := range []string{"a/b", "m~n", "~1", "~/~0/", "/~"} {
+		doc, _ := json.Marshal(map[string]string{key: "ok"})
+
+		out, err := merge.GetValue(doc, merge.EncodePointer(key))
+		if err != nil {
+			t.Errorf("key %q: unexpected error: %s", key, err)
+			continue
+		}
+		if string(out) != `"ok"` {
+			t.Errorf("key %q: expected \"ok\", got %s", key, out)
+		}
+	}
+}
+
+func TestFlatten(t *testing.T) {
+	doc := `{"a": {"b": 1, "c/d": [true, null]}, "e": [], "f": {}, "g": [{"h": "x"}]}`
+
+	exp := map[string]string{
+		"/a/b":      `1`,
+		"/a/c~1d/0": `true`,
+		"/a/c~1d/1": `null`,
+		"/e":        `[]`,
+		"/f":        `{}`,
+		"/g/0/h":    `"x"`,
+	}
+
+	out, err := merge.Flatten([]byte(doc))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(out) != len(exp) {
+		t.Errorf("expected %d pointers, got %d", len(exp), len(out))
+	}
+
+	for pointer, val := range exp {
+		if string(out[pointer]) != val {
+			t.Errorf("pointer %q: expected %s, got %s", pointer, val, out[pointer])
+		}
+	}
+}
+
+func TestFlattenDuplicateKeys(t *testing.T) {
+	cases := []struct {
+		doc string
+		exp map[string]string
+	}{
+		{`{"a":1,"a":2}`, map[string]string{"/a": `1`}},
+		{`{"a":{"b":1},"a":{"c":2}}`, map[string]string{"/a/b": `1`}},
+	}
+
+	for _, c := range cases {
+		out, err := merge.Flatten([]byte(c.doc))
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if len(out) != len(c.exp) {
+			t.Errorf("doc %s: expected %d pointers, got %q", c.doc, len(c.exp), out)
+		}
+		for pointer, val := range c.exp {
+			if string(out[pointer]) != val {
+				t.Errorf("doc %s, pointer %q: expected %s, got %s", c.doc, pointer, val, out[pointer])
+			}
+		}
+	}
+}
+
+func TestFlattenRoundTrip(t *testing.T) {
+	docs := []string{
+		`{"a": {"b": 1, "c/d": [true, null]}, "e": [], "f": {}, "g": [{"h~": "x"}]}`,
+		`{"a":1,"a":2,"b":{"c":1},"b":{"d":2}}`,
+		`[[1,{"a":[],"a":3}],"x"]`,
+		`"x"`,
+	}
+
+	for _, doc := range docs {
+		out, err := merge.Flatten([]byte(doc))
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		for pointer, val := range out {
+			got, err := merge.GetValue([]byte(doc), pointer)
+			if err != nil {
+				t.Errorf("doc %s, pointer %q: unexpected error: %s", doc, pointer, err)
+				continue
+			}
+			if string(got) != string(val) {
+				t.Errorf("doc %s, pointer %q: Flatten gave %s, GetValue gave %s", doc, pointer, val, got)
+			}
+		}
+	}
+}
+
+func TestFlattenScalarDocument(t *testing.T) {
+	out, err := merge.Flatten([]byte(`"x"`))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(out) != 1 || string(out[""]) != `"x"` {
+		t.Errorf(`expected only the root pointer "", got %q`, out)
+	}
+}
+
+func compareJSONValue(a, b string) bool {
+	var objA, objB interface{}
+	json.Unmarshal([]byte(a), &objA)
+	json.Unmarshal([]byte(b), &objB)
+
+	return reflect.DeepEqual(objA, objB)
+}